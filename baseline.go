@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultBaselineFileName is used when the scan/baseline_update tools aren't
+// given an explicit baseline_path input.
+const defaultBaselineFileName = ".archlint-baseline.json"
+
+// baselineEntry is one accepted finding in .archlint-baseline.json.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	RuleID      string `json:"rule_id"`
+	Path        string `json:"path"`
+	Snippet     string `json:"snippet"`
+}
+
+// baselineFile is the on-disk shape of .archlint-baseline.json.
+type baselineFile struct {
+	Entries []baselineEntry `json:"entries"`
+}
+
+// loadBaseline reads and parses the baseline file at path. A missing file is
+// not an error: it just means no findings are suppressed yet.
+func loadBaseline(path string) (*baselineFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var bf baselineFile
+	if err := json.Unmarshal(data, &bf); err != nil {
+		return nil, err
+	}
+	return &bf, nil
+}
+
+// writeBaseline overwrites the baseline file at path with one entry per
+// finding.
+func writeBaseline(path string, findings []finding, workspaceRoot string) error {
+	bf := baselineFile{Entries: make([]baselineEntry, 0, len(findings))}
+	for _, f := range findings {
+		relPath := relativeTo(workspaceRoot, f.file)
+		bf.Entries = append(bf.Entries, baselineEntry{
+			Fingerprint: computeFingerprint(f.ruleID, relPath, f.snippet),
+			RuleID:      f.ruleID,
+			Path:        relPath,
+			Snippet:     normalizeSnippet(f.snippet),
+		})
+	}
+
+	data, err := json.MarshalIndent(&bf, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// filterByBaseline splits findings into those not covered by the baseline
+// (kept) and counts how many were suppressed. It also returns the baseline
+// entries that no longer match any current finding, so stale suppressions
+// can be surfaced and eventually pruned.
+func filterByBaseline(findings []finding, baseline *baselineFile, workspaceRoot string) (kept []finding, suppressedCount int, stale []baselineEntry) {
+	if baseline == nil || len(baseline.Entries) == 0 {
+		return findings, 0, nil
+	}
+
+	matched := make(map[string]bool, len(baseline.Entries))
+	byFingerprint := make(map[string]bool, len(baseline.Entries))
+	for _, entry := range baseline.Entries {
+		byFingerprint[entry.Fingerprint] = true
+	}
+
+	for _, f := range findings {
+		fingerprint := computeFingerprint(f.ruleID, relativeTo(workspaceRoot, f.file), f.snippet)
+		if byFingerprint[fingerprint] {
+			matched[fingerprint] = true
+			suppressedCount++
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	for _, entry := range baseline.Entries {
+		if !matched[entry.Fingerprint] {
+			stale = append(stale, entry)
+		}
+	}
+
+	return kept, suppressedCount, stale
+}
+
+// computeFingerprint hashes (ruleID, relativePath, normalizedSnippet) with
+// SHA-256 so a baseline entry survives unrelated reformatting elsewhere in
+// the file but still changes if the offending line itself changes.
+func computeFingerprint(ruleID, relPath, snippet string) string {
+	h := sha256.New()
+	h.Write([]byte(ruleID))
+	h.Write([]byte{0})
+	h.Write([]byte(relPath))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeSnippet(snippet)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeSnippet trims the offending line and collapses internal
+// whitespace runs to a single space, so the fingerprint survives
+// re-indentation or gofmt-style reformatting.
+func normalizeSnippet(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// relativeTo returns path relative to root, falling back to path itself if
+// it can't be made relative (e.g. it's already relative, or on another
+// volume on Windows).
+func relativeTo(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sourceLine returns the (1-based) line-th line of the file at path, or ""
+// if it can't be read or is out of range. Used to populate a finding's
+// snippet when the caller only has a file/line pair, not the fileInfo.lines
+// slice (e.g. a circular-dependency cycle spanning multiple files).
+func sourceLine(path string, line int) string {
+	lines, err := readLines(path)
+	if err != nil || line-1 < 0 || line-1 >= len(lines) {
+		return ""
+	}
+	return lines[line-1]
+}
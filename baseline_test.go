@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestComputeFingerprintStableUnderReindent(t *testing.T) {
+	a := computeFingerprint("ARCH-002", "pkg/file.go", "\tdb.Query(query)")
+	b := computeFingerprint("ARCH-002", "pkg/file.go", "    db.Query(query)")
+	c := computeFingerprint("ARCH-002", "pkg/file.go", "db.Query(query)  ")
+
+	if a != b || b != c {
+		t.Fatalf("fingerprint changed under re-indentation: %q, %q, %q", a, b, c)
+	}
+}
+
+func TestComputeFingerprintChangesWithInputs(t *testing.T) {
+	base := computeFingerprint("ARCH-002", "pkg/file.go", "db.Query(query)")
+
+	if got := computeFingerprint("ARCH-003", "pkg/file.go", "db.Query(query)"); got == base {
+		t.Error("fingerprint unchanged after changing ruleID")
+	}
+	if got := computeFingerprint("ARCH-002", "pkg/other.go", "db.Query(query)"); got == base {
+		t.Error("fingerprint unchanged after changing path")
+	}
+	if got := computeFingerprint("ARCH-002", "pkg/file.go", "db.Exec(query)"); got == base {
+		t.Error("fingerprint unchanged after changing snippet")
+	}
+}
+
+func TestNormalizeSnippet(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"\tdb.Query(query)", "db.Query(query)"},
+		{"    db.Query(query)", "db.Query(query)"},
+		{"db.Query(query)  ", "db.Query(query)"},
+		{"db.Query(  query )", "db.Query( query )"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSnippet(tt.line); got != tt.want {
+			t.Errorf("normalizeSnippet(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nox-hq/nox/sdk"
+)
+
+// configFileNames are the filenames looked up, in order, while walking up
+// from workspaceRoot.
+var configFileNames = []string{".archlint.yaml", ".archlint.yml"}
+
+// archlintConfig is the parsed form of a .archlint.yaml file.
+type archlintConfig struct {
+	Rules    map[string]ruleConfig          `yaml:"rules"`
+	Patterns map[string]map[string][]string `yaml:"patterns"` // category (crypto/auth/handler) -> language -> extra regexes
+	Layers   map[string][]string            `yaml:"layers"`   // layer name -> path globs
+	Allow    map[string][]string            `yaml:"allow"`    // layer -> layers it may import from
+	GoAST    *bool                          `yaml:"go_ast"`   // use the AST backend for .go files; defaults to true
+}
+
+// useGoAST reports whether the AST-backed Go parser should be used, which is
+// the default absent an explicit opt-out in .archlint.yaml.
+func (cfg *archlintConfig) useGoAST() bool {
+	if cfg == nil || cfg.GoAST == nil {
+		return true
+	}
+	return *cfg.GoAST
+}
+
+// ruleConfig holds the per-rule overrides supported in .archlint.yaml.
+type ruleConfig struct {
+	Enabled    *bool          `yaml:"enabled"`
+	Thresholds map[string]int `yaml:"thresholds"`
+	Exclude    []string       `yaml:"exclude"`
+}
+
+// ruleEnabled reports whether ruleID is enabled under cfg. A nil cfg, or a
+// rule with no explicit entry, is enabled by default.
+func (cfg *archlintConfig) ruleEnabled(ruleID string) bool {
+	if cfg == nil {
+		return true
+	}
+	rc, ok := cfg.Rules[ruleID]
+	if !ok || rc.Enabled == nil {
+		return true
+	}
+	return *rc.Enabled
+}
+
+// threshold returns the configured override for (ruleID, key), falling back
+// to def when cfg is nil or no override is set.
+func (cfg *archlintConfig) threshold(ruleID, key string, def int) int {
+	if cfg == nil {
+		return def
+	}
+	rc, ok := cfg.Rules[ruleID]
+	if !ok {
+		return def
+	}
+	if v, ok := rc.Thresholds[key]; ok {
+		return v
+	}
+	return def
+}
+
+// excluded reports whether relPath is excluded from ruleID by a path glob in
+// .archlint.yaml.
+func (cfg *archlintConfig) excluded(ruleID, relPath string) bool {
+	if cfg == nil {
+		return false
+	}
+	rc, ok := cfg.Rules[ruleID]
+	if !ok {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range rc.Exclude {
+		if matchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// extraPatterns returns the additional regexes configured for (category,
+// language), e.g. category "crypto", language "go".
+func (cfg *archlintConfig) extraPatterns(category, language string) []string {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Patterns[category][language]
+}
+
+// layers returns the declared layer -> path-glob map, or nil when cfg has no
+// layered architecture ruleset.
+func (cfg *archlintConfig) layers() map[string][]string {
+	if cfg == nil {
+		return nil
+	}
+	return cfg.Layers
+}
+
+// compileExtra compiles a set of user-supplied regex patterns, silently
+// skipping any that fail to compile — a typo in .archlint.yaml shouldn't take
+// down the whole rule, just that one extra pattern.
+func compileExtra(patterns []string) []*regexp.Regexp {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			continue
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled
+}
+
+// matchesAny reports whether line matches base or any of the extra patterns.
+func matchesAny(line string, base *regexp.Regexp, extra []*regexp.Regexp) bool {
+	if base.MatchString(line) {
+		return true
+	}
+	return matchesAnyOf(line, extra)
+}
+
+// matchesAnyOf reports whether line matches any of the given patterns.
+func matchesAnyOf(line string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// layerOf returns the name of the first declared layer whose path globs match
+// relPath, and false if relPath doesn't fall under any declared layer. Layer
+// names are visited in sorted order rather than cfg.Layers' map iteration
+// order, so that a path matching more than one layer's globs resolves to the
+// same layer on every run.
+func (cfg *archlintConfig) layerOf(relPath string) (string, bool) {
+	relPath = filepath.ToSlash(relPath)
+	layers := make([]string, 0, len(cfg.Layers))
+	for layer := range cfg.Layers {
+		layers = append(layers, layer)
+	}
+	sort.Strings(layers)
+	for _, layer := range layers {
+		for _, glob := range cfg.Layers[layer] {
+			if matchGlob(glob, relPath) {
+				return layer, true
+			}
+		}
+	}
+	return "", false
+}
+
+// layerAllowed reports whether fromLayer is permitted to import toLayer under
+// the declared allow matrix. A layer may always import itself.
+func (cfg *archlintConfig) layerAllowed(fromLayer, toLayer string) bool {
+	if fromLayer == toLayer {
+		return true
+	}
+	for _, allowed := range cfg.Allow[fromLayer] {
+		if allowed == toLayer {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLayerRules emits ARCH-005 findings for import edges that cross a
+// declared layer boundary not present in the allow matrix. It reuses the same
+// package-identity resolution as the ARCH-001 cycle detector so layer
+// membership lines up with the same notion of "package".
+func checkLayerRules(sink *findingSink, files []fileInfo, workspaceRoot, goModulePath string, cfg *archlintConfig) {
+	pkgToRelPath := make(map[string]string, len(files))
+	for _, fi := range files {
+		pkg := packageIdentity(fi.path, fi.ext, workspaceRoot, goModulePath)
+		if _, ok := pkgToRelPath[pkg]; !ok {
+			relPath, _ := filepath.Rel(workspaceRoot, fi.path)
+			pkgToRelPath[pkg] = filepath.ToSlash(relPath)
+		}
+	}
+
+	for _, fi := range files {
+		fromRelPath, _ := filepath.Rel(workspaceRoot, fi.path)
+		fromRelPath = filepath.ToSlash(fromRelPath)
+		if cfg.excluded("ARCH-005", fromRelPath) {
+			continue
+		}
+		fromLayer, ok := cfg.layerOf(fromRelPath)
+		if !ok {
+			continue
+		}
+
+		for _, imp := range fi.imports {
+			toPkg := importedPackageIdentity(imp.module, fi.path, fi.ext, workspaceRoot, goModulePath)
+			if toPkg == "" {
+				continue
+			}
+			toRelPath, ok := pkgToRelPath[toPkg]
+			if !ok {
+				continue
+			}
+			toLayer, ok := cfg.layerOf(toRelPath)
+			if !ok || cfg.layerAllowed(fromLayer, toLayer) {
+				continue
+			}
+
+			sink.add(finding{
+				ruleID:     "ARCH-005",
+				severity:   sdk.SeverityMedium,
+				confidence: sdk.ConfidenceMedium,
+				message:    fmt.Sprintf("Layer violation: %s may not import %s (%s -> %s)", fromLayer, toLayer, fromRelPath, imp.module),
+				file:       fi.path,
+				startLine:  imp.line,
+				endLine:    imp.line,
+				snippet:    imp.snippet,
+				metadata: map[string]string{
+					"from_layer":      fromLayer,
+					"to_layer":        toLayer,
+					"imported_module": imp.module,
+				},
+			})
+		}
+	}
+}
+
+// loadConfig walks up from workspaceRoot looking for .archlint.yaml or
+// .archlint.yml and parses the first one found. It returns (nil, nil) when no
+// config file exists anywhere above workspaceRoot. A parse error is returned
+// rather than silently discarded, so the caller can surface it as a finding
+// instead of aborting the scan.
+func loadConfig(workspaceRoot string) (*archlintConfig, error) {
+	dir := workspaceRoot
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var cfg archlintConfig
+			if err := yaml.Unmarshal(data, &cfg); err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", path, err)
+			}
+			return &cfg, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// matchGlob reports whether path matches pattern, where pattern may use "*"
+// to match any run of characters within a path segment and "**" to match any
+// number of path segments (including zero). Both pattern and path are
+// expected to be slash-separated.
+func matchGlob(pattern, path string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchGlobSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		if matchGlobSegments(pattern[1:], path) {
+			return true
+		}
+		if len(path) == 0 {
+			return false
+		}
+		return matchGlobSegments(pattern, path[1:])
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], path[1:])
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		// "**" matching zero segments.
+		{"a/**/b", "a/b", true},
+		{"**/foo.go", "foo.go", true},
+		// "**" matching multiple segments.
+		{"a/**/b", "a/x/y/b", true},
+		{"**/foo.go", "dir/sub/foo.go", true},
+		// "**" alone matches anything, including nothing.
+		{"**", "", true},
+		{"**", "a/b/c", true},
+		// A single "*" only matches within one segment.
+		{"a/*/c", "a/b/c", true},
+		{"a/*/c", "a/b/x/c", false},
+		// Non-matching cases.
+		{"a/**/b", "a/b/c", false},
+		{"internal/**", "cmd/main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/nox-hq/nox/sdk"
+)
+
+// finding is an in-memory, pre-baseline-filter representation of an ARCH-*
+// finding. The check* functions build these instead of writing straight into
+// an sdk.ResponseBuilder so that handleScan can filter them against
+// .archlint-baseline.json before any of them reach the response.
+type finding struct {
+	ruleID     string
+	severity   sdk.Severity
+	confidence sdk.Confidence
+	message    string
+	file       string
+	startLine  int
+	endLine    int
+	snippet    string // offending source line, used for baseline fingerprinting
+	metadata   map[string]string
+}
+
+// findingSink collects findings as the check* functions run over the
+// workspace.
+type findingSink struct {
+	findings []finding
+}
+
+func (s *findingSink) add(f finding) {
+	s.findings = append(s.findings, f)
+}
+
+// sortFindings puts findings into a stable order so that two scans of the
+// same workspace produce byte-identical output regardless of the worker-pool
+// completion order the findings were collected in. File path is the primary
+// key since that's what a reader (or a SARIF/CI diff) scans by; start line,
+// then rule ID, break ties among findings at the same file.
+func sortFindings(findings []finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		if a.startLine != b.startLine {
+			return a.startLine < b.startLine
+		}
+		return a.ruleID < b.ruleID
+	})
+}
+
+// flushFindings writes every collected finding into resp, in the same shape the
+// check* functions used to build directly.
+func flushFindings(resp *sdk.ResponseBuilder, findings []finding) {
+	for _, f := range findings {
+		fb := resp.Finding(f.ruleID, f.severity, f.confidence, f.message).At(f.file, f.startLine, f.endLine)
+		for k, v := range f.metadata {
+			fb = fb.WithMetadata(k, v)
+		}
+		fb.Done()
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	pluginv1 "github.com/nox-hq/nox/gen/nox/plugin/v1"
 	"github.com/nox-hq/nox/sdk"
@@ -82,11 +85,14 @@ func buildServer() *sdk.PluginServer {
 		Capability("arch-lint", "Architecture risk and design lint for source code").
 		Tool("scan", "Detect circular dependencies, god objects, security-critical code mixing, and missing abstraction layers", true).
 		Done().
+		Tool("baseline_update", "Run a scan and write/overwrite the baseline file with all current findings", true).
+		Done().
 		Safety(sdk.WithRiskClass(sdk.RiskPassive)).
 		Build()
 
 	return sdk.NewPluginServer(manifest).
-		HandleTool("scan", handleScan)
+		HandleTool("scan", handleScan).
+		HandleTool("baseline_update", handleBaselineUpdate)
 }
 
 func handleScan(ctx context.Context, req sdk.ToolRequest) (*pluginv1.InvokeToolResponse, error) {
@@ -101,72 +107,336 @@ func handleScan(ctx context.Context, req sdk.ToolRequest) (*pluginv1.InvokeToolR
 		return resp.Build(), nil
 	}
 
-	// Collect import information for circular dependency detection.
-	importGraph := make(map[string][]string) // file -> imported modules
+	cfg, cfgErr := loadConfig(workspaceRoot)
+	if cfgErr != nil {
+		resp.Finding(
+			"ARCH-CONFIG",
+			sdk.SeverityHigh,
+			sdk.ConfidenceHigh,
+			fmt.Sprintf("Failed to parse archlint config: %v", cfgErr),
+		).
+			At(workspaceRoot, 1, 1).
+			Done()
+		cfg = nil // Fall back to hard-coded defaults rather than aborting the scan.
+	}
 
-	var files []fileInfo
+	_, findings, err := runScan(ctx, workspaceRoot, cfg, parallelismInput(req), maxFileBytesInput(req))
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.WalkDir(workspaceRoot, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-		if ctx.Err() != nil {
-			return ctx.Err()
+	baselinePath, _ := req.Input["baseline_path"].(string)
+	if baselinePath == "" {
+		baselinePath = filepath.Join(workspaceRoot, defaultBaselineFileName)
+	} else if !filepath.IsAbs(baselinePath) {
+		baselinePath = filepath.Join(workspaceRoot, baselinePath)
+	}
+
+	baseline, baselineErr := loadBaseline(baselinePath)
+	if baselineErr != nil {
+		resp.Finding(
+			"ARCH-CONFIG",
+			sdk.SeverityHigh,
+			sdk.ConfidenceHigh,
+			fmt.Sprintf("Failed to parse baseline file: %v", baselineErr),
+		).
+			At(baselinePath, 1, 1).
+			Done()
+		baseline = nil
+	}
+
+	kept, suppressedCount, stale := filterByBaseline(findings, baseline, workspaceRoot)
+	flushFindings(resp, kept)
+
+	if suppressedCount > 0 {
+		resp.Finding(
+			"ARCH-BASELINE-INFO",
+			sdk.SeverityLow,
+			sdk.ConfidenceHigh,
+			"Findings suppressed by baseline",
+		).
+			At(baselinePath, 1, 1).
+			WithMetadata("baseline_suppressed_count", fmt.Sprintf("%d", suppressedCount)).
+			Done()
+	}
+
+	for _, entry := range stale {
+		resp.Finding(
+			"ARCH-BASELINE-STALE",
+			sdk.SeverityLow,
+			sdk.ConfidenceMedium,
+			fmt.Sprintf("Baseline entry for %s (%s) no longer matches any current finding", entry.Path, entry.RuleID),
+		).
+			At(baselinePath, 1, 1).
+			WithMetadata("fingerprint", entry.Fingerprint).
+			WithMetadata("rule_id", entry.RuleID).
+			WithMetadata("path", entry.Path).
+			Done()
+	}
+
+	format, _ := req.Input["format"].(string)
+	if format == "sarif" {
+		sarifDoc, sarifErr := buildSARIF(kept, workspaceRoot)
+		if sarifErr != nil {
+			return nil, fmt.Errorf("building sarif output: %w", sarifErr)
 		}
-		if d.IsDir() {
-			if skippedDirs[d.Name()] {
-				return filepath.SkipDir
+		resp.Artifact("results.sarif", "application/sarif+json", sarifDoc).Done()
+	}
+
+	return resp.Build(), nil
+}
+
+// handleBaselineUpdate runs a scan and writes every current finding to the
+// baseline file, overwriting whatever was there before. This is how a team
+// accepts the current state of a legacy codebase so only new findings block
+// CI from then on.
+func handleBaselineUpdate(ctx context.Context, req sdk.ToolRequest) (*pluginv1.InvokeToolResponse, error) {
+	workspaceRoot, _ := req.Input["workspace_root"].(string)
+	if workspaceRoot == "" {
+		workspaceRoot = req.WorkspaceRoot
+	}
+
+	resp := sdk.NewResponse()
+
+	if workspaceRoot == "" {
+		return resp.Build(), nil
+	}
+
+	cfg, _ := loadConfig(workspaceRoot) // Config errors here surface via a normal scan; just fall back.
+
+	_, findings, err := runScan(ctx, workspaceRoot, cfg, parallelismInput(req), maxFileBytesInput(req))
+	if err != nil {
+		return nil, err
+	}
+
+	baselinePath, _ := req.Input["baseline_path"].(string)
+	if baselinePath == "" {
+		baselinePath = filepath.Join(workspaceRoot, defaultBaselineFileName)
+	} else if !filepath.IsAbs(baselinePath) {
+		baselinePath = filepath.Join(workspaceRoot, baselinePath)
+	}
+
+	if err := writeBaseline(baselinePath, findings, workspaceRoot); err != nil {
+		return nil, fmt.Errorf("writing baseline: %w", err)
+	}
+
+	resp.Finding(
+		"ARCH-BASELINE-INFO",
+		sdk.SeverityLow,
+		sdk.ConfidenceHigh,
+		fmt.Sprintf("Wrote %d entries to baseline", len(findings)),
+	).
+		At(baselinePath, 1, 1).
+		WithMetadata("entry_count", fmt.Sprintf("%d", len(findings))).
+		Done()
+
+	return resp.Build(), nil
+}
+
+// defaultMaxFileBytes is the max_file_bytes guard applied when a scan
+// doesn't specify its own. Files larger than this are skipped with an
+// informational finding instead of being read into memory, so a handful of
+// multi-megabyte generated files (bundles, vendored dumps, fixtures) can't
+// blow up scan memory or latency.
+const defaultMaxFileBytes = 5 << 20 // 5 MiB
+
+// scanResult is what a worker sends back for one file: either a parsed
+// fileInfo, or an informational finding when the file was skipped.
+type scanResult struct {
+	info    fileInfo
+	parsed  bool
+	skipped *finding
+}
+
+// parallelismInput reads the optional "parallelism" tool input, returning 0
+// (runScan's "use GOMAXPROCS" default) when it's absent or not a number.
+func parallelismInput(req sdk.ToolRequest) int {
+	switch v := req.Input["parallelism"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// maxFileBytesInput reads the optional "max_file_bytes" tool input,
+// returning 0 (runScan's "use defaultMaxFileBytes" default) when it's absent
+// or not a number.
+func maxFileBytesInput(req sdk.ToolRequest) int64 {
+	switch v := req.Input["max_file_bytes"].(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+// runScan walks workspaceRoot with a bounded pool of worker goroutines,
+// parsing every source file (and precomputing its ARCH-003/ARCH-004
+// signals) in parallel, then runs all enabled ARCH-* checks over the merged
+// result. Both the scan and baseline_update tools share this.
+//
+// parallelism caps the number of concurrent parse workers; <= 0 means
+// runtime.GOMAXPROCS(0). maxFileBytes caps the size of a file that will be
+// read at all; <= 0 means defaultMaxFileBytes. Both ctx.Done() and the
+// workspace walk are honored cooperatively by the producer and every worker.
+func runScan(ctx context.Context, workspaceRoot string, cfg *archlintConfig, parallelism int, maxFileBytes int64) ([]fileInfo, []finding, error) {
+	goModulePath := findGoModulePath(workspaceRoot)
+
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	if maxFileBytes <= 0 {
+		maxFileBytes = defaultMaxFileBytes
+	}
+
+	paths := make(chan string, parallelism*4)
+	results := make(chan scanResult, parallelism*4)
+
+	var workers sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for path := range paths {
+				if ctx.Err() != nil {
+					continue // Drain the rest of paths without doing work.
+				}
+
+				if stat, statErr := os.Stat(path); statErr == nil && stat.Size() > maxFileBytes {
+					results <- scanResult{skipped: &finding{
+						ruleID:     "ARCH-SKIPPED-LARGE-FILE",
+						severity:   sdk.SeverityLow,
+						confidence: sdk.ConfidenceHigh,
+						message:    fmt.Sprintf("Skipped %d-byte file (exceeds max_file_bytes=%d)", stat.Size(), maxFileBytes),
+						file:       path,
+						startLine:  1,
+						endLine:    1,
+						metadata:   map[string]string{"size_bytes": fmt.Sprintf("%d", stat.Size())},
+					}}
+					continue
+				}
+
+				info, parseErr := parseFile(path, filepath.Ext(path), cfg)
+				if parseErr != nil {
+					continue
+				}
+				trimRetainedLines(&info)
+				results <- scanResult{info: info, parsed: true}
 			}
-			return nil
-		}
+		}()
+	}
 
-		ext := filepath.Ext(path)
-		if !sourceExtensions[ext] {
-			return nil
-		}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	walkErr := make(chan error, 1)
+	go func() {
+		defer close(paths)
+		walkErr <- filepath.WalkDir(workspaceRoot, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if d.IsDir() {
+				if skippedDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
 
-		info, parseErr := parseFile(path, ext)
-		if parseErr != nil {
+			ext := filepath.Ext(path)
+			if !sourceExtensions[ext] {
+				return nil
+			}
+
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 			return nil
-		}
-		files = append(files, info)
+		})
+	}()
 
-		relPath, _ := filepath.Rel(workspaceRoot, path)
-		if relPath == "" {
-			relPath = path
+	sink := &findingSink{}
+	var files []fileInfo
+	for result := range results {
+		if result.skipped != nil {
+			sink.add(*result.skipped)
+			continue
 		}
-		for _, imp := range info.imports {
-			importGraph[relPath] = append(importGraph[relPath], imp.module)
+		if result.parsed {
+			files = append(files, result.info)
 		}
+	}
+
+	if err := <-walkErr; err != nil && err != context.Canceled {
+		return nil, nil, fmt.Errorf("walking workspace: %w", err)
+	}
 
-		return nil
-	})
-	if err != nil && err != context.Canceled {
-		return nil, fmt.Errorf("walking workspace: %w", err)
+	// Worker completion order is nondeterministic, so files (and anything
+	// derived from their order, e.g. which import edge buildPackageGraph
+	// keeps between a repeated pair of packages) must be put back into a
+	// stable order before any analysis pass runs over them.
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	// ARCH-001: Build a single package-level import graph and run Tarjan's SCC
+	// algorithm over it once, rather than re-deriving it per file.
+	if cfg.ruleEnabled("ARCH-001") {
+		pkgGraph, edgeRefs := buildPackageGraph(files, workspaceRoot, goModulePath)
+		cycles := tarjanSCCs(pkgGraph)
+		reportCircularDeps(sink, cycles, edgeRefs)
+	}
+
+	// ARCH-005: Evaluate declared layer rules, if any, against the same
+	// import edges used for cycle detection.
+	if cfg.ruleEnabled("ARCH-005") && len(cfg.layers()) > 0 {
+		checkLayerRules(sink, files, workspaceRoot, goModulePath, cfg)
 	}
 
 	// Analyze collected data.
 	for _, fi := range files {
-		// ARCH-001: Check for circular dependency indicators.
-		checkCircularDeps(resp, fi, importGraph, workspaceRoot)
+		relPath, _ := filepath.Rel(workspaceRoot, fi.path)
+		relPath = filepath.ToSlash(relPath)
 
 		// ARCH-002: Check for god objects.
-		checkGodObject(resp, fi)
+		if cfg.ruleEnabled("ARCH-002") && !cfg.excluded("ARCH-002", relPath) {
+			checkGodObject(sink, fi, cfg)
+		}
 
 		// ARCH-003: Check for security-critical code without separation.
-		checkSecurityMixing(resp, fi)
+		if cfg.ruleEnabled("ARCH-003") && !cfg.excluded("ARCH-003", relPath) {
+			checkSecurityMixing(sink, fi)
+		}
 
 		// ARCH-004: Check for missing abstraction layer.
-		checkMissingAbstraction(resp, fi)
+		if cfg.ruleEnabled("ARCH-004") && !cfg.excluded("ARCH-004", relPath) {
+			checkMissingAbstraction(sink, fi)
+		}
 	}
 
-	return resp.Build(), nil
+	sortFindings(sink.findings)
+
+	return files, sink.findings, nil
 }
 
-// importRef represents a detected import statement.
+// importRef represents a detected import statement. snippet is the import
+// line's source text, captured at parse time so later checks (ARCH-005) that
+// cite the import don't need to index back into fi.lines, which may have
+// been dropped for large files (see trimRetainedLines).
 type importRef struct {
-	module string
-	line   int
+	module  string
+	line    int
+	snippet string
 }
 
 // fileInfo holds parsed information about a source file.
@@ -177,10 +447,77 @@ type fileInfo struct {
 	imports   []importRef
 	exports   int
 	lineCount int
+
+	// astParsed is set when this fileInfo came from an AST-backed parser
+	// (currently only Go) rather than the line-regex scanner.
+	astParsed bool
+
+	// The fields below are precomputed once, at parse time, by whichever
+	// backend produced this fileInfo (including any config-supplied extra
+	// patterns), so checkSecurityMixing and checkMissingAbstraction never
+	// need to re-scan fi.lines themselves.
+	hasCrypto     bool
+	hasAuth       bool
+	hasBizLogic   bool
+	hasDBAccess   bool
+	hasHTTPRoutes bool
+	cryptoLine    int
+	authLine      int
+	cryptoSnippet string
+	authSnippet   string
+	isHandler     bool
+	sqlLines      []int
+	sqlSnippets   []string // parallel to sqlLines
+
+	// firstLine is fi.lines[0], kept even after lines is dropped for large
+	// files so checkGodObject can still cite a snippet.
+	firstLine string
+	// linesDropped is set when fi.lines was discarded after precomputation to
+	// bound memory on large files (see maxRetainedFileLines).
+	linesDropped bool
+}
+
+// maxRetainedFileLines is the line count above which runScan drops all of a
+// file's lines after precomputation, so a handful of huge files in a large repo can't
+// balloon total memory use. Checks that need an offending line's text
+// (ARCH-003, ARCH-004) capture it as a snippet at parse time instead of
+// indexing back into fi.lines later.
+const maxRetainedFileLines = 2000
+
+// trimRetainedLines drops fi.lines for files above maxRetainedFileLines,
+// once every check's precomputed signal (firstLine, crypto/auth snippets,
+// SQL snippets) has already been captured by the parser. Everything
+// downstream reads those precomputed fields, never fi.lines directly, so
+// this is safe to do unconditionally after parsing.
+func trimRetainedLines(info *fileInfo) {
+	if info.lineCount <= maxRetainedFileLines {
+		return
+	}
+	info.lines = nil
+	info.linesDropped = true
 }
 
-// parseFile reads a file and extracts imports, exports, and line metadata.
-func parseFile(filePath, ext string) (fileInfo, error) {
+// parseFile reads a file and extracts imports, exports, line metadata, and
+// the precomputed security/abstraction signals used by ARCH-003/ARCH-004.
+// For Go files, it prefers the AST-backed parser (see astgo.go) when enabled
+// by config, falling back to the line-regex scanner on parse failure.
+func parseFile(filePath, ext string, cfg *archlintConfig) (fileInfo, error) {
+	if ext == ".go" && cfg.useGoAST() {
+		if info, err := parseGoFileAST(filePath, cfg); err == nil {
+			return info, nil
+		}
+		// Fall through to the regex scanner below on a parse error (e.g. a
+		// file that isn't valid Go, or uses build-tag-gated syntax).
+	}
+	return parseFileRegex(filePath, ext, cfg)
+}
+
+// parseFileRegex reads a file and extracts imports, exports, line metadata,
+// and security/abstraction signals, all in a single pass over its lines
+// using the line-oriented regex patterns (plus any config-supplied extra
+// patterns for the file's language). It's the only backend for
+// .py/.js/.ts, and the fallback backend for .go.
+func parseFileRegex(filePath, ext string, cfg *archlintConfig) (fileInfo, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return fileInfo{}, err
@@ -192,18 +529,30 @@ func parseFile(filePath, ext string) (fileInfo, error) {
 		ext:  ext,
 	}
 
+	language := extToLanguage(ext)
+	extraCrypto := compileExtra(cfg.extraPatterns("crypto", language))
+	extraAuth := compileExtra(cfg.extraPatterns("auth", language))
+	extraHandler := compileExtra(cfg.extraPatterns("handler", language))
+
+	var sawHandlerPattern bool
+	var sqlCandidateLines []int
+	var sqlCandidateSnippets []string
+
 	scanner := bufio.NewScanner(f)
 	lineNum := 0
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 		info.lines = append(info.lines, line)
+		if lineNum == 1 {
+			info.firstLine = line
+		}
 
 		// Extract imports.
 		switch ext {
 		case ".go":
 			if m := reGoImport.FindStringSubmatch(line); len(m) > 1 {
-				info.imports = append(info.imports, importRef{module: m[1], line: lineNum})
+				info.imports = append(info.imports, importRef{module: m[1], line: lineNum, snippet: line})
 			}
 			if reGoExport.MatchString(line) {
 				info.exports++
@@ -215,7 +564,7 @@ func parseFile(filePath, ext string) (fileInfo, error) {
 					mod = m[2]
 				}
 				if mod != "" {
-					info.imports = append(info.imports, importRef{module: mod, line: lineNum})
+					info.imports = append(info.imports, importRef{module: mod, line: lineNum, snippet: line})
 				}
 			}
 			if rePyExport.MatchString(line) || rePyAllExport.MatchString(line) {
@@ -223,200 +572,489 @@ func parseFile(filePath, ext string) (fileInfo, error) {
 			}
 		case ".js", ".ts":
 			if m := reJSImport.FindStringSubmatch(line); len(m) > 1 && m[1] != "" {
-				info.imports = append(info.imports, importRef{module: m[1], line: lineNum})
+				info.imports = append(info.imports, importRef{module: m[1], line: lineNum, snippet: line})
 			}
 			if reJSExport.MatchString(line) {
 				info.exports++
 			}
 		}
+
+		// ARCH-003 signals: crypto/auth/business-logic/db/http-route patterns.
+		switch ext {
+		case ".go":
+			if !info.hasCrypto && matchesAny(line, reCryptoGo, extraCrypto) {
+				info.hasCrypto = true
+				info.cryptoLine = lineNum
+				info.cryptoSnippet = line
+			}
+			if !info.hasAuth && matchesAny(line, reAuthGo, extraAuth) {
+				info.hasAuth = true
+				info.authLine = lineNum
+				info.authSnippet = line
+			}
+		case ".py":
+			if !info.hasCrypto && matchesAny(line, reCryptoPy, extraCrypto) {
+				info.hasCrypto = true
+				info.cryptoLine = lineNum
+				info.cryptoSnippet = line
+			}
+			if !info.hasAuth && matchesAny(line, reAuthPy, extraAuth) {
+				info.hasAuth = true
+				info.authLine = lineNum
+				info.authSnippet = line
+			}
+		case ".js", ".ts":
+			if !info.hasCrypto && matchesAny(line, reCryptoJS, extraCrypto) {
+				info.hasCrypto = true
+				info.cryptoLine = lineNum
+				info.cryptoSnippet = line
+			}
+			if !info.hasAuth && matchesAny(line, reAuthJS, extraAuth) {
+				info.hasAuth = true
+				info.authLine = lineNum
+				info.authSnippet = line
+			}
+		}
+		if reBizLogic.MatchString(line) {
+			info.hasBizLogic = true
+		}
+		if reDBAccess.MatchString(line) {
+			info.hasDBAccess = true
+		}
+		if reHTTPRoute.MatchString(line) {
+			info.hasHTTPRoutes = true
+		}
+
+		// ARCH-004 signals: is this a handler/controller file, and which
+		// lines look like direct SQL. Handler status can only be confirmed
+		// once the whole file is scanned, so candidate SQL lines are held
+		// until then.
+		if matchesAny(line, reHandlerFunc, extraHandler) {
+			sawHandlerPattern = true
+		}
+		if reSQLInHandler.MatchString(line) {
+			sqlCandidateLines = append(sqlCandidateLines, lineNum)
+			sqlCandidateSnippets = append(sqlCandidateSnippets, strings.TrimSpace(line))
+		}
 	}
 
 	info.lineCount = lineNum
+	info.isHandler = sawHandlerPattern
+	if sawHandlerPattern {
+		info.sqlLines = sqlCandidateLines
+		info.sqlSnippets = sqlCandidateSnippets
+	}
+
 	return info, scanner.Err()
 }
 
-// checkCircularDeps detects mutual import patterns between files.
-func checkCircularDeps(resp *sdk.ResponseBuilder, fi fileInfo, importGraph map[string][]string, workspaceRoot string) {
-	relPath, _ := filepath.Rel(workspaceRoot, fi.path)
-	if relPath == "" {
-		relPath = fi.path
-	}
+// maxReportedCycles caps the number of ARCH-001 findings emitted per scan so
+// a pathological repo with a dense SCC can't explode the response.
+const maxReportedCycles = 50
+
+// pkgEdge is a single import edge between two packages, remembered so a
+// reported cycle can point at the file/line that introduced it.
+type pkgEdge struct {
+	file string
+	line int
+}
+
+// buildPackageGraph derives a package-identity import graph from the parsed
+// files, keyed by package rather than by file. It returns the adjacency list
+// together with the first observed file/line for each edge, so a later cycle
+// report can cite the offending import on the reporting side.
+func buildPackageGraph(files []fileInfo, workspaceRoot, goModulePath string) (map[string][]string, map[string]map[string]pkgEdge) {
+	graph := make(map[string][]string)
+	edges := make(map[string]map[string]pkgEdge)
+	seen := make(map[string]map[string]bool)
+
+	for _, fi := range files {
+		fromPkg := packageIdentity(fi.path, fi.ext, workspaceRoot, goModulePath)
 
-	myDir := filepath.Dir(relPath)
-	myPkg := filepath.Base(myDir)
+		for _, imp := range fi.imports {
+			toPkg := importedPackageIdentity(imp.module, fi.path, fi.ext, workspaceRoot, goModulePath)
+			if toPkg == "" || toPkg == fromPkg {
+				continue // Ignore unresolved imports and self-loops.
+			}
 
-	for _, imp := range fi.imports {
-		// Check if any other file imports our package while we import theirs.
-		impBase := filepath.Base(imp.module)
-		for otherFile, otherImports := range importGraph {
-			if otherFile == relPath {
+			if seen[fromPkg] == nil {
+				seen[fromPkg] = make(map[string]bool)
+			}
+			if seen[fromPkg][toPkg] {
 				continue
 			}
-			otherDir := filepath.Dir(otherFile)
-			otherPkg := filepath.Base(otherDir)
-
-			// Mutual import: we import their package, they import ours.
-			if impBase == otherPkg || strings.HasSuffix(imp.module, "/"+otherPkg) {
-				for _, otherImp := range otherImports {
-					otherImpBase := filepath.Base(otherImp)
-					if otherImpBase == myPkg || strings.HasSuffix(otherImp, "/"+myPkg) {
-						resp.Finding(
-							"ARCH-001",
-							sdk.SeverityMedium,
-							sdk.ConfidenceHigh,
-							fmt.Sprintf("Circular dependency risk: %s imports %s which imports back", relPath, imp.module),
-						).
-							At(fi.path, imp.line, imp.line).
-							WithMetadata("imported_module", imp.module).
-							WithMetadata("language", extToLanguage(fi.ext)).
-							Done()
-						return // Report once per file.
-					}
-				}
+			seen[fromPkg][toPkg] = true
+
+			graph[fromPkg] = append(graph[fromPkg], toPkg)
+			if edges[fromPkg] == nil {
+				edges[fromPkg] = make(map[string]pkgEdge)
 			}
+			edges[fromPkg][toPkg] = pkgEdge{file: fi.path, line: imp.line}
+		}
+
+		if _, ok := graph[fromPkg]; !ok {
+			graph[fromPkg] = nil // Ensure every package is a node, even with no outgoing edges.
 		}
 	}
+
+	return graph, edges
 }
 
-// checkGodObject flags files that exceed the line threshold with many exports.
-func checkGodObject(resp *sdk.ResponseBuilder, fi fileInfo) {
-	if fi.lineCount > godFileThreshold && fi.exports >= godExportThreshold {
-		resp.Finding(
-			"ARCH-002",
-			sdk.SeverityMedium,
-			sdk.ConfidenceMedium,
-			fmt.Sprintf("God object/file detected: %d lines with %d exports", fi.lineCount, fi.exports),
-		).
-			At(fi.path, 1, 1).
-			WithMetadata("line_count", fmt.Sprintf("%d", fi.lineCount)).
-			WithMetadata("export_count", fmt.Sprintf("%d", fi.exports)).
-			WithMetadata("language", extToLanguage(fi.ext)).
-			Done()
+// packageIdentity derives the package identity of the file at path, used as
+// the "from" side of import edges.
+func packageIdentity(path, ext, workspaceRoot, goModulePath string) string {
+	relPath, _ := filepath.Rel(workspaceRoot, path)
+	if relPath == "" {
+		relPath = path
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	switch ext {
+	case ".go":
+		dir := filepath.ToSlash(filepath.Dir(relPath))
+		if goModulePath == "" {
+			return dir
+		}
+		if dir == "." {
+			return goModulePath
+		}
+		return goModulePath + "/" + dir
+	case ".py":
+		dir := filepath.ToSlash(filepath.Dir(relPath))
+		base := strings.TrimSuffix(filepath.Base(relPath), ".py")
+		if base == "__init__" {
+			return strings.ReplaceAll(dir, "/", ".")
+		}
+		if dir == "." {
+			return base
+		}
+		return strings.ReplaceAll(dir, "/", ".") + "." + base
+	default: // .js, .ts
+		return strings.TrimSuffix(relPath, ext)
 	}
 }
 
-// checkSecurityMixing detects crypto/auth logic mixed with business logic in the same file.
-func checkSecurityMixing(resp *sdk.ResponseBuilder, fi fileInfo) {
-	hasCrypto := false
-	hasAuth := false
-	hasBizLogic := false
-	hasDBAccess := false
-	hasHTTPRoutes := false
+// importedPackageIdentity resolves the module string found in an import
+// statement to the same package-identity space produced by packageIdentity,
+// so edges between the two line up. Imports that can't be resolved to a
+// package inside workspaceRoot (e.g. a third-party module) return "".
+func importedPackageIdentity(module, fromPath, ext, workspaceRoot, goModulePath string) string {
+	switch ext {
+	case ".go":
+		if goModulePath == "" || !strings.HasPrefix(module, goModulePath) {
+			return ""
+		}
+		return module
+	case ".py":
+		if strings.HasPrefix(module, ".") {
+			// Relative import: resolve against the importing file's package dir.
+			dir := filepath.ToSlash(filepath.Dir(fromPath))
+			rel, _ := filepath.Rel(workspaceRoot, dir)
+			return strings.ReplaceAll(filepath.ToSlash(rel), "/", ".") + "." + strings.TrimLeft(module, ".")
+		}
+		return module
+	default: // .js, .ts
+		if !strings.HasPrefix(module, ".") {
+			return "" // Not a local import.
+		}
+		dir := filepath.Dir(fromPath)
+		resolved := filepath.Join(dir, module)
+		rel, _ := filepath.Rel(workspaceRoot, resolved)
+		return strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel))
+	}
+}
 
-	var cryptoLine, authLine int
+// tarjanState tracks per-node bookkeeping for the iterative Tarjan SCC walk.
+type tarjanState struct {
+	index   int
+	lowlink int
+	onStack bool
+	visited bool
+}
 
-	for i, line := range fi.lines {
-		switch fi.ext {
-		case ".go":
-			if reCryptoGo.MatchString(line) {
-				hasCrypto = true
-				if cryptoLine == 0 {
-					cryptoLine = i + 1
-				}
-			}
-			if reAuthGo.MatchString(line) {
-				hasAuth = true
-				if authLine == 0 {
-					authLine = i + 1
+// tarjanSCCs runs Tarjan's strongly connected components algorithm over the
+// package import graph using an iterative DFS (an explicit stack rather than
+// recursion, so it doesn't blow the goroutine stack on large repos). It
+// returns every SCC of size >= 2, which is exactly the set of package-level
+// import cycles.
+func tarjanSCCs(graph map[string][]string) [][]string {
+	states := make(map[string]*tarjanState, len(graph))
+	for pkg := range graph {
+		states[pkg] = &tarjanState{}
+	}
+
+	var counter int
+	var stack []string
+	var sccs [][]string
+
+	// callFrame tracks DFS progress for one node across resumptions of the
+	// iterative walk: which node we're visiting and how far into its
+	// successor list we've gotten.
+	type callFrame struct {
+		node string
+		i    int
+	}
+
+	// Map iteration order is randomized, so the root node the DFS starts
+	// from each "outer" pass would otherwise vary run-to-run; that doesn't
+	// change which cycles are found, but it does change the order sccs
+	// comes back in, which feeds the finding order and the maxReportedCycles
+	// cutoff. Visit roots in a fixed order instead.
+	roots := make([]string, 0, len(graph))
+	for pkg := range graph {
+		roots = append(roots, pkg)
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		if states[root].visited {
+			continue
+		}
+
+		var frames []callFrame
+		frames = append(frames, callFrame{node: root})
+		states[root].visited = true
+		states[root].index = counter
+		states[root].lowlink = counter
+		counter++
+		stack = append(stack, root)
+		states[root].onStack = true
+
+		for len(frames) > 0 {
+			top := &frames[len(frames)-1]
+			st := states[top.node]
+
+			if top.i < len(graph[top.node]) {
+				succ := graph[top.node][top.i]
+				top.i++
+
+				succState := states[succ]
+				if succState == nil {
+					continue // Edge points outside the node set (shouldn't happen; defensive).
 				}
-			}
-		case ".py":
-			if reCryptoPy.MatchString(line) {
-				hasCrypto = true
-				if cryptoLine == 0 {
-					cryptoLine = i + 1
+				if !succState.visited {
+					succState.visited = true
+					succState.index = counter
+					succState.lowlink = counter
+					counter++
+					stack = append(stack, succ)
+					succState.onStack = true
+					frames = append(frames, callFrame{node: succ})
+				} else if succState.onStack {
+					if succState.index < st.lowlink {
+						st.lowlink = succState.index
+					}
 				}
+				continue
 			}
-			if reAuthPy.MatchString(line) {
-				hasAuth = true
-				if authLine == 0 {
-					authLine = i + 1
+
+			// Done with top.node's successors: pop the DFS frame and
+			// propagate lowlink to the parent frame, if any.
+			frames = frames[:len(frames)-1]
+			if len(frames) > 0 {
+				parent := states[frames[len(frames)-1].node]
+				if st.lowlink < parent.lowlink {
+					parent.lowlink = st.lowlink
 				}
 			}
-		case ".js", ".ts":
-			if reCryptoJS.MatchString(line) {
-				hasCrypto = true
-				if cryptoLine == 0 {
-					cryptoLine = i + 1
+
+			if st.lowlink == st.index {
+				var scc []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					member := stack[i]
+					stack = stack[:i]
+					states[member].onStack = false
+					scc = append(scc, member)
+					if member == top.node {
+						break
+					}
 				}
-			}
-			if reAuthJS.MatchString(line) {
-				hasAuth = true
-				if authLine == 0 {
-					authLine = i + 1
+				if len(scc) >= 2 {
+					sccs = append(sccs, scc)
 				}
 			}
 		}
+	}
 
-		if reBizLogic.MatchString(line) {
-			hasBizLogic = true
+	return sccs
+}
+
+// reportCircularDeps emits one ARCH-001 finding per detected cycle (capped at
+// maxReportedCycles), citing every package in the cycle and the file/line of
+// one offending import edge within it.
+func reportCircularDeps(sink *findingSink, cycles [][]string, edgeRefs map[string]map[string]pkgEdge) {
+	reported := 0
+	for _, cycle := range cycles {
+		if reported >= maxReportedCycles {
+			break
 		}
-		if reDBAccess.MatchString(line) {
-			hasDBAccess = true
+
+		members := make(map[string]bool, len(cycle))
+		for _, pkg := range cycle {
+			members[pkg] = true
 		}
-		if reHTTPRoute.MatchString(line) {
-			hasHTTPRoutes = true
+
+		// Find one edge whose endpoints are both in the cycle, to cite as
+		// the offending import on the reporting side. edgeRefs[pkg] is a
+		// map, so its "to" targets are visited in sorted order to keep the
+		// chosen edge (and thus the reported file/line) stable across runs.
+		var reportFile string
+		var reportLine int
+		var fromPkg, toPkg string
+		for _, pkg := range cycle {
+			tos := make([]string, 0, len(edgeRefs[pkg]))
+			for to := range edgeRefs[pkg] {
+				tos = append(tos, to)
+			}
+			sort.Strings(tos)
+			for _, to := range tos {
+				if members[to] {
+					fromPkg, toPkg = pkg, to
+					ref := edgeRefs[pkg][to]
+					reportFile, reportLine = ref.file, ref.line
+					break
+				}
+			}
+			if reportFile != "" {
+				break
+			}
+		}
+		if reportFile == "" {
+			// Shouldn't happen for a real SCC, but fall back to something
+			// reportable rather than dropping the finding.
+			reportFile = cycle[0]
+			reportLine = 1
 		}
+
+		sink.add(finding{
+			ruleID:     "ARCH-001",
+			severity:   sdk.SeverityMedium,
+			confidence: sdk.ConfidenceHigh,
+			message:    fmt.Sprintf("Circular dependency: %s", strings.Join(cycle, " -> ")),
+			file:       reportFile,
+			startLine:  reportLine,
+			endLine:    reportLine,
+			snippet:    sourceLine(reportFile, reportLine),
+			metadata: map[string]string{
+				"cycle_packages": strings.Join(cycle, ","),
+				"cycle_length":   fmt.Sprintf("%d", len(cycle)),
+				"from_package":   fromPkg,
+				"to_package":     toPkg,
+			},
+		})
+
+		reported++
+	}
+}
+
+// checkGodObject flags files that exceed the line threshold with many exports.
+func checkGodObject(sink *findingSink, fi fileInfo, cfg *archlintConfig) {
+	lineThreshold := cfg.threshold("ARCH-002", "god_file_lines", godFileThreshold)
+	exportThreshold := cfg.threshold("ARCH-002", "god_export_count", godExportThreshold)
+
+	if fi.lineCount > lineThreshold && fi.exports >= exportThreshold {
+		sink.add(finding{
+			ruleID:     "ARCH-002",
+			severity:   sdk.SeverityMedium,
+			confidence: sdk.ConfidenceMedium,
+			message:    fmt.Sprintf("God object/file detected: %d lines with %d exports", fi.lineCount, fi.exports),
+			file:       fi.path,
+			startLine:  1,
+			endLine:    1,
+			snippet:    fi.firstLine,
+			metadata: map[string]string{
+				"line_count":   fmt.Sprintf("%d", fi.lineCount),
+				"export_count": fmt.Sprintf("%d", fi.exports),
+				"language":     extToLanguage(fi.ext),
+			},
+		})
 	}
+}
 
-	securityCritical := hasCrypto || hasAuth
-	businessMixed := hasBizLogic || hasDBAccess || hasHTTPRoutes
+// checkSecurityMixing detects crypto/auth logic mixed with business logic in
+// the same file. Every field it reads was precomputed at parse time (by
+// parseFileRegex or parseGoFileAST, including any config-supplied extra
+// patterns), so this is a pure decision over fi — no line re-scanning.
+func checkSecurityMixing(sink *findingSink, fi fileInfo) {
+	securityCritical := fi.hasCrypto || fi.hasAuth
+	businessMixed := fi.hasBizLogic || fi.hasDBAccess || fi.hasHTTPRoutes
 
 	if securityCritical && businessMixed {
-		reportLine := cryptoLine
+		reportLine := fi.cryptoLine
+		snippet := fi.cryptoSnippet
 		if reportLine == 0 {
-			reportLine = authLine
+			reportLine = fi.authLine
+			snippet = fi.authSnippet
 		}
 		detail := "crypto"
-		if hasAuth {
+		if fi.hasAuth {
 			detail = "auth"
 		}
-		if hasCrypto && hasAuth {
+		if fi.hasCrypto && fi.hasAuth {
 			detail = "crypto/auth"
 		}
-		resp.Finding(
-			"ARCH-003",
-			sdk.SeverityHigh,
-			sdk.ConfidenceHigh,
-			fmt.Sprintf("Security-critical code (%s) mixed with business logic in same file", detail),
-		).
-			At(fi.path, reportLine, reportLine).
-			WithMetadata("has_crypto", fmt.Sprintf("%t", hasCrypto)).
-			WithMetadata("has_auth", fmt.Sprintf("%t", hasAuth)).
-			WithMetadata("has_business_logic", fmt.Sprintf("%t", hasBizLogic)).
-			WithMetadata("language", extToLanguage(fi.ext)).
-			Done()
+		sink.add(finding{
+			ruleID:     "ARCH-003",
+			severity:   sdk.SeverityHigh,
+			confidence: sdk.ConfidenceHigh,
+			message:    fmt.Sprintf("Security-critical code (%s) mixed with business logic in same file", detail),
+			file:       fi.path,
+			startLine:  reportLine,
+			endLine:    reportLine,
+			snippet:    snippet,
+			metadata: map[string]string{
+				"has_crypto":         fmt.Sprintf("%t", fi.hasCrypto),
+				"has_auth":           fmt.Sprintf("%t", fi.hasAuth),
+				"has_business_logic": fmt.Sprintf("%t", fi.hasBizLogic),
+				"language":           extToLanguage(fi.ext),
+			},
+		})
 	}
 }
 
-// checkMissingAbstraction detects direct database calls in handler/controller files.
-func checkMissingAbstraction(resp *sdk.ResponseBuilder, fi fileInfo) {
-	isHandler := false
-
-	for _, line := range fi.lines {
-		if reHandlerFunc.MatchString(line) {
-			isHandler = true
-			break
-		}
+// checkMissingAbstraction detects direct database calls in handler/
+// controller files. fi.isHandler and fi.sqlLines/fi.sqlSnippets were
+// precomputed at parse time, so this is a pure decision over fi — no line
+// re-scanning.
+func checkMissingAbstraction(sink *findingSink, fi fileInfo) {
+	if !fi.isHandler {
+		return
 	}
 
-	if !isHandler {
-		return
+	for i, lineNum := range fi.sqlLines {
+		line := fi.sqlSnippets[i]
+		sink.add(finding{
+			ruleID:     "ARCH-004",
+			severity:   sdk.SeverityLow,
+			confidence: sdk.ConfidenceMedium,
+			message:    fmt.Sprintf("Missing abstraction layer: direct database call in handler: %s", line),
+			file:       fi.path,
+			startLine:  lineNum,
+			endLine:    lineNum,
+			snippet:    line,
+			metadata:   map[string]string{"language": extToLanguage(fi.ext)},
+		})
 	}
+}
 
-	for i, line := range fi.lines {
-		if reSQLInHandler.MatchString(line) {
-			resp.Finding(
-				"ARCH-004",
-				sdk.SeverityLow,
-				sdk.ConfidenceMedium,
-				fmt.Sprintf("Missing abstraction layer: direct database call in handler: %s", strings.TrimSpace(line)),
-			).
-				At(fi.path, i+1, i+1).
-				WithMetadata("language", extToLanguage(fi.ext)).
-				Done()
+// findGoModulePath reads the go.mod directly in workspaceRoot and returns its
+// declared module path, or "" if there is none there; parent directories are
+// not searched. This is used to
+// give Go packages a stable, globally-qualified identity in the import graph
+// instead of a bare directory name, so two directories with the same base
+// name in different trees aren't mistaken for the same package.
+func findGoModulePath(workspaceRoot string) string {
+	data, err := os.ReadFile(filepath.Join(workspaceRoot, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module"))
 		}
 	}
+	return ""
 }
 
 // extToLanguage maps file extensions to human-readable language names.
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestTarjanSCCsFindsCycle(t *testing.T) {
+	// A -> B -> C -> A is a single 3-node cycle.
+	graph := map[string][]string{
+		"A": {"B"},
+		"B": {"C"},
+		"C": {"A"},
+	}
+
+	sccs := tarjanSCCs(graph)
+	if len(sccs) != 1 {
+		t.Fatalf("got %d SCCs, want 1: %v", len(sccs), sccs)
+	}
+
+	got := make(map[string]bool, len(sccs[0]))
+	for _, pkg := range sccs[0] {
+		got[pkg] = true
+	}
+	for _, pkg := range []string{"A", "B", "C"} {
+		if !got[pkg] {
+			t.Errorf("cycle %v missing member %q", sccs[0], pkg)
+		}
+	}
+}
+
+func TestTarjanSCCsIgnoresSelfLoop(t *testing.T) {
+	// A self-loop isn't a cycle an import graph cares about (and
+	// buildPackageGraph never produces one), so a lone node pointing at
+	// itself must not be reported as an SCC.
+	graph := map[string][]string{
+		"A": {"A"},
+	}
+
+	if sccs := tarjanSCCs(graph); len(sccs) != 0 {
+		t.Fatalf("got %v, want no SCCs for a self-loop", sccs)
+	}
+}
+
+func TestTarjanSCCsNoCycleInDAG(t *testing.T) {
+	graph := map[string][]string{
+		"A": {"B"},
+		"B": {"C"},
+		"C": nil,
+	}
+
+	if sccs := tarjanSCCs(graph); len(sccs) != 0 {
+		t.Fatalf("got %v, want no SCCs for an acyclic graph", sccs)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/nox-hq/nox/sdk"
+)
+
+// sarifSchemaURI and sarifVersion identify the SARIF 2.1.0 spec this output
+// conforms to.
+const (
+	sarifSchemaURI = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion   = "2.1.0"
+)
+
+// sarifFingerprintKey names the partialFingerprints entry carrying the same
+// fingerprint the baseline feature uses, so a SARIF-consuming dashboard's
+// dedup lines up with the plugin's own suppression fingerprints.
+const sarifFingerprintKey = "archLintFingerprint/v1"
+
+// ruleInfo describes one ARCH-* rule for the SARIF tool.driver.rules array.
+type ruleInfo struct {
+	name        string
+	description string
+	helpURI     string
+}
+
+// archRules holds the rule metadata surfaced in SARIF output. ARCH-BASELINE-*
+// and ARCH-CONFIG findings are operational, not architectural, so they're
+// left out of the rules catalog (and of SARIF output entirely).
+var archRules = map[string]ruleInfo{
+	"ARCH-001": {
+		name:        "CircularDependency",
+		description: "Import cycle detected between packages",
+		helpURI:     "https://github.com/nox-hq/nox-plugin-arch-lint#arch-001",
+	},
+	"ARCH-002": {
+		name:        "GodObject",
+		description: "Large file with many exports, a sign of insufficient separation of concerns",
+		helpURI:     "https://github.com/nox-hq/nox-plugin-arch-lint#arch-002",
+	},
+	"ARCH-003": {
+		name:        "SecurityCodeMixing",
+		description: "Security-critical code mixed with business logic in the same file",
+		helpURI:     "https://github.com/nox-hq/nox-plugin-arch-lint#arch-003",
+	},
+	"ARCH-004": {
+		name:        "MissingAbstractionLayer",
+		description: "Direct database access from a handler/controller file",
+		helpURI:     "https://github.com/nox-hq/nox-plugin-arch-lint#arch-004",
+	},
+	"ARCH-005": {
+		name:        "LayerViolation",
+		description: "Import crosses a layer boundary not present in the declared allow matrix",
+		helpURI:     "https://github.com/nox-hq/nox-plugin-arch-lint#arch-005",
+	},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     sarifText              `json:"shortDescription"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifText         `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// sarifLevel maps an sdk.Severity to the SARIF result/rule level vocabulary.
+func sarifLevel(severity sdk.Severity) string {
+	switch severity {
+	case sdk.SeverityHigh:
+		return "error"
+	case sdk.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// buildSARIF renders findings as a SARIF 2.1.0 log with a single run, one
+// rule per distinct ARCH-* rule ID present, and one result per finding.
+func buildSARIF(findings []finding, workspaceRoot string) ([]byte, error) {
+	seenRules := make(map[string]bool)
+	var rules []sarifRule
+	var results []sarifResult
+
+	for _, f := range findings {
+		if !seenRules[f.ruleID] {
+			seenRules[f.ruleID] = true
+			info, ok := archRules[f.ruleID]
+			rule := sarifRule{ID: f.ruleID, DefaultConfiguration: sarifRuleConfiguration{Level: sarifLevel(f.severity)}}
+			if ok {
+				rule.Name = info.name
+				rule.ShortDescription = sarifText{Text: info.description}
+				rule.HelpURI = info.helpURI
+			} else {
+				rule.ShortDescription = sarifText{Text: f.message}
+			}
+			rules = append(rules, rule)
+		}
+
+		relPath := relativeTo(workspaceRoot, f.file)
+		results = append(results, sarifResult{
+			RuleID:  f.ruleID,
+			Level:   sarifLevel(f.severity),
+			Message: sarifText{Text: f.message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(relPath)},
+					Region:           sarifRegion{StartLine: f.startLine, EndLine: f.endLine},
+				},
+			}},
+			PartialFingerprints: map[string]string{
+				sarifFingerprintKey: computeFingerprint(f.ruleID, relPath, f.snippet),
+			},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchemaURI,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "nox-arch-lint",
+				InformationURI: "https://github.com/nox-hq/nox-plugin-arch-lint",
+				Version:        version,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	data, err := json.MarshalIndent(&log, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}